@@ -0,0 +1,134 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Batch carries multiple Responses as a single JSON array, matching
+// the batch request/response convention used by Google APIs, where
+// each sub-response is identified by its request's ID.
+type Batch struct {
+	Responses []*Response
+}
+
+// NewBatch returns an empty Batch.
+func NewBatch() *Batch {
+	return &Batch{Responses: make([]*Response, 0)}
+}
+
+// Add appends r to the batch.
+func (b *Batch) Add(r *Response) {
+	b.Responses = append(b.Responses, r)
+}
+
+// ByID returns the Response in the batch whose ID matches id, or nil
+// if none does.
+func (b *Batch) ByID(id string) *Response {
+	for _, r := range b.Responses {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// MarshalJSON marshals a Batch as a plain JSON array of Responses.
+func (b *Batch) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.Responses)
+}
+
+// UnmarshalJSON unmarshals a JSON array of Responses into a Batch.
+func (b *Batch) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &b.Responses)
+}
+
+// NewBatchFromHTTPResponse reads and parses r's body as a Batch.
+func NewBatchFromHTTPResponse(r http.Response) (*Batch, error) {
+	defer r.Body.Close()
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	b := NewBatch()
+	err = json.Unmarshal(body, b)
+	return b, err
+}
+
+// Write marshals the batch to a byte slice, calling SetItemCount on
+// each Response's Data first.
+func (b *Batch) Write() ([]byte, error) {
+	for _, r := range b.Responses {
+		r.Data.SetItemCount()
+	}
+	return json.Marshal(b)
+}
+
+// WriteToHTTPResponse writes the batch to w as a JSON array, setting
+// Content-Type to application/json.
+func (b *Batch) WriteToHTTPResponse(w http.ResponseWriter) error {
+	body, err := b.Write()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(body)
+	return err
+}
+
+// BatchHandler returns an http.Handler that parses its request body as
+// a Batch, dispatches each sub-Response to handle in order, and
+// writes the collated results back as a Batch. If handle panics or
+// returns nil for a sub-request, that failure is recorded in the
+// Error field of the matching sub-response instead of failing the
+// whole batch.
+func BatchHandler(handle func(*Response) *Response) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		in := NewBatch()
+		if err := json.Unmarshal(body, in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		out := NewBatch()
+		for _, req := range in.Responses {
+			out.Add(dispatchBatchItem(handle, req))
+		}
+		if err := out.WriteToHTTPResponse(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// dispatchBatchItem calls handle for req, converting a panic or a nil
+// result into an error Response carrying req's ID.
+func dispatchBatchItem(handle func(*Response) *Response, req *Response) (resp *Response) {
+	defer func() {
+		if p := recover(); p != nil {
+			resp = batchItemError(req, fmt.Sprintf("%v", p))
+		}
+	}()
+	resp = handle(req)
+	if resp == nil {
+		resp = batchItemError(req, "batch handler returned no response")
+	}
+	return resp
+}
+
+func batchItemError(req *Response, message string) *Response {
+	resp := req.Copy()
+	resp.ID = req.ID
+	resp.Error = Error{Code: http.StatusInternalServerError, Message: message}
+	return resp
+}
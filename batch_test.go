@@ -0,0 +1,112 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBatchAddAndByID(t *testing.T) {
+	b := NewBatch()
+	r1 := New()
+	r1.ID = "1"
+	r2 := New()
+	r2.ID = "2"
+	b.Add(r1)
+	b.Add(r2)
+
+	if got := b.ByID("2"); got != r2 {
+		t.Error("Test failed")
+	}
+	if got := b.ByID("missing"); got != nil {
+		t.Error("Test failed")
+	}
+}
+
+func TestBatchWriteToHTTPResponse(t *testing.T) {
+	b := NewBatch()
+	r := New()
+	r.ID = "1"
+	r.Data.AddItem(CarItem{"red", "SUV"})
+	b.Add(r)
+
+	w := httptest.NewRecorder()
+	if err := b.WriteToHTTPResponse(w); err != nil {
+		t.Fatal(err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json Content-Type, got %q", ct)
+	}
+	var out []Response
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].ID != "1" {
+		t.Error("Test failed")
+	}
+}
+
+func TestNewBatchFromHTTPResponse(t *testing.T) {
+	r1 := New()
+	r1.ID = "1"
+	r2 := New()
+	r2.ID = "2"
+	body, err := json.Marshal([]*Response{r1, r2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := http.Response{Body: ioutil.NopCloser(bytes.NewReader(body))}
+	b, err := NewBatchFromHTTPResponse(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Responses) != 2 || b.ByID("2") == nil {
+		t.Error("Test failed")
+	}
+}
+
+func TestBatchHandler(t *testing.T) {
+	handler := BatchHandler(func(req *Response) *Response {
+		resp := New()
+		resp.ID = req.ID
+		if req.ID == "boom" {
+			panic("kaboom")
+		}
+		resp.Data.AddItem(CarItem{"red", "SUV"})
+		return resp
+	})
+
+	r1 := New()
+	r1.ID = "1"
+	r2 := New()
+	r2.ID = "boom"
+	body, err := json.Marshal([]*Response{r1, r2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/batch", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var out []Response
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(out))
+	}
+	if out[0].Error.Code != 0 {
+		t.Error("expected first sub-response to succeed")
+	}
+	if out[1].ID != "boom" || out[1].Error.Code != http.StatusInternalServerError {
+		t.Error("expected second sub-response to carry a recovered error")
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maskNode is one node of a parsed FieldMask, keyed by the field name
+// that led to it. A node with no children is a leaf: the field itself
+// is kept in full. A node with children selects only those children
+// of the field.
+type maskNode struct {
+	children map[string]*maskNode
+}
+
+func (n *maskNode) merge(children map[string]*maskNode) {
+	if n.children == nil {
+		n.children = make(map[string]*maskNode)
+	}
+	for k, v := range children {
+		n.children[k] = v
+	}
+}
+
+// FieldMask is a parsed Google-style partial response field mask, as
+// used by Data.Fields, supporting dotted or slashed paths
+// ("error/errors/message") and parenthesized sub-selections
+// ("data.items(color,type)"). Mask paths are rooted at the Response
+// envelope. As a convenience for the older flat style produced by
+// AddField/GetFields ("color,type"), a mask made up entirely of bare
+// field names with no nested selections is instead applied to each
+// item in Data.Items; see WriteWithFieldMask.
+type FieldMask struct {
+	nodes map[string]*maskNode
+}
+
+// fields that are always preserved by WriteWithFieldMask and always
+// permitted by ReadWithFieldMask, regardless of what the mask selects.
+var alwaysKeptFields = map[string]bool{
+	"apiVersion": true,
+	"id":         true,
+	"error":      true,
+}
+
+// isFlatItemFieldMask reports whether every top-level node in nodes is
+// a leaf (no children): the legacy flat CSV style ("color,type") that
+// names fields on each item directly, rather than structured,
+// envelope-rooted paths such as "data.items(color,type)".
+func isFlatItemFieldMask(nodes map[string]*maskNode) bool {
+	for _, n := range nodes {
+		if n != nil && len(n.children) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseFieldMask parses s, a comma separated list of field paths,
+// into a FieldMask.
+func ParseFieldMask(s string) (*FieldMask, error) {
+	nodes, err := parseMaskGroup(s)
+	if err != nil {
+		return nil, err
+	}
+	return &FieldMask{nodes: nodes}, nil
+}
+
+// parseMaskGroup parses the comma separated contents of a mask or of
+// a parenthesized sub-selection.
+func parseMaskGroup(s string) (map[string]*maskNode, error) {
+	nodes := make(map[string]*maskNode)
+	for _, part := range splitTopLevel(s, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if err := addMaskPath(nodes, part); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// addMaskPath parses one dotted or slashed field path and merges it
+// into nodes.
+func addMaskPath(nodes map[string]*maskNode, path string) error {
+	seg, rest, group, err := nextMaskSegment(path)
+	if err != nil {
+		return err
+	}
+	n, ok := nodes[seg]
+	if !ok {
+		n = &maskNode{}
+		nodes[seg] = n
+	}
+	switch {
+	case group != "":
+		children, err := parseMaskGroup(group)
+		if err != nil {
+			return err
+		}
+		n.merge(children)
+	case rest != "":
+		if n.children == nil {
+			n.children = make(map[string]*maskNode)
+		}
+		return addMaskPath(n.children, rest)
+	}
+	return nil
+}
+
+// nextMaskSegment splits the leading field name off path, returning
+// whatever follows it as rest (a further dotted/slashed path) or
+// group (the contents of a parenthesized sub-selection).
+func nextMaskSegment(path string) (seg, rest, group string, err error) {
+	i := 0
+	for i < len(path) && path[i] != '.' && path[i] != '/' && path[i] != '(' {
+		i++
+	}
+	seg = path[:i]
+	if seg == "" {
+		return "", "", "", fmt.Errorf("googlejson: empty field name in mask %q", path)
+	}
+	if i == len(path) {
+		return seg, "", "", nil
+	}
+	if path[i] != '(' {
+		return seg, path[i+1:], "", nil
+	}
+	depth := 1
+	j := i + 1
+	for j < len(path) && depth > 0 {
+		switch path[j] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		j++
+	}
+	if depth != 0 {
+		return "", "", "", fmt.Errorf("googlejson: unbalanced parens in mask %q", path)
+	}
+	group = path[i+1 : j-1]
+	rest = strings.TrimPrefix(strings.TrimPrefix(path[j:], "."), "/")
+	return seg, rest, group, nil
+}
+
+// splitTopLevel splits s on sep, ignoring occurrences of sep nested
+// inside parens.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// WriteWithFieldMask marshals r like Write, but if Data.Fields names a
+// field mask, prunes any key not selected by the mask before writing.
+// apiVersion, id, and error are always preserved.
+//
+// As a convenience for the flat style produced by AddField/GetFields
+// (e.g. "color,type"), a mask made up entirely of bare field names is
+// instead applied per item, pruning each entry of Data.Items down to
+// those fields while leaving the rest of Data and the envelope alone.
+func (r *Response) WriteWithFieldMask() ([]byte, error) {
+	if r.Data.Fields == "" {
+		return r.Write()
+	}
+	mask, err := ParseFieldMask(r.Data.Fields)
+	if err != nil {
+		return nil, err
+	}
+	r.Data.SetItemCount()
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	var full map[string]interface{}
+	if err := json.Unmarshal(b, &full); err != nil {
+		return nil, err
+	}
+
+	if isFlatItemFieldMask(mask.nodes) {
+		if data, ok := full["data"].(map[string]interface{}); ok {
+			if items, ok := data["items"]; ok {
+				data["items"] = pruneMaskValue(items, &maskNode{children: mask.nodes})
+			}
+		}
+		return json.Marshal(full)
+	}
+
+	pruned := pruneMaskMap(full, mask.nodes)
+	for field := range alwaysKeptFields {
+		if v, ok := full[field]; ok {
+			pruned[field] = v
+		}
+	}
+	return json.Marshal(pruned)
+}
+
+func pruneMaskMap(m map[string]interface{}, nodes map[string]*maskNode) map[string]interface{} {
+	out := make(map[string]interface{})
+	for key, node := range nodes {
+		if v, ok := m[key]; ok {
+			out[key] = pruneMaskValue(v, node)
+		}
+	}
+	return out
+}
+
+func pruneMaskValue(v interface{}, node *maskNode) interface{} {
+	if node == nil || len(node.children) == 0 {
+		return v
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return pruneMaskMap(val, node.children)
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			if m, ok := item.(map[string]interface{}); ok {
+				out[i] = pruneMaskMap(m, node.children)
+			} else {
+				out[i] = item
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// ReadWithFieldMask unmarshals b into r. If Data.Fields names a field
+// mask and strict is true, any field present in b that the mask does
+// not select is rejected as an error instead of being silently
+// dropped by encoding/json. As in WriteWithFieldMask, a purely flat
+// mask is checked against each item in Data.Items instead of the full
+// envelope.
+func (r *Response) ReadWithFieldMask(b []byte, strict bool) error {
+	if err := json.Unmarshal(b, r); err != nil {
+		return err
+	}
+	if !strict || r.Data.Fields == "" {
+		return nil
+	}
+	mask, err := ParseFieldMask(r.Data.Fields)
+	if err != nil {
+		return err
+	}
+	var full map[string]interface{}
+	if err := json.Unmarshal(b, &full); err != nil {
+		return err
+	}
+
+	if isFlatItemFieldMask(mask.nodes) {
+		data, ok := full["data"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		items, _ := data["items"].([]interface{})
+		for _, item := range items {
+			im, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if err := checkFieldMask(im, mask.nodes, "data.items."); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return checkFieldMask(full, mask.nodes, "")
+}
+
+func checkFieldMask(m map[string]interface{}, nodes map[string]*maskNode, prefix string) error {
+	for key, v := range m {
+		if alwaysKeptFields[key] {
+			continue
+		}
+		node, ok := nodes[key]
+		if !ok {
+			return fmt.Errorf("googlejson: field %q not present in field mask", prefix+key)
+		}
+		if node == nil || len(node.children) == 0 {
+			continue
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			if err := checkFieldMask(val, node.children, prefix+key+"."); err != nil {
+				return err
+			}
+		case []interface{}:
+			for _, item := range val {
+				if im, ok := item.(map[string]interface{}); ok {
+					if err := checkFieldMask(im, node.children, prefix+key+"."); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseFieldMask(t *testing.T) {
+	mask, err := ParseFieldMask("data.items(color,type),error/errors/message")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, ok := mask.nodes["data"]
+	if !ok {
+		t.Fatal("expected top level \"data\" node")
+	}
+	items, ok := data.children["items"]
+	if !ok {
+		t.Fatal("expected \"data.items\" node")
+	}
+	if _, ok := items.children["color"]; !ok {
+		t.Error("expected \"data.items.color\" node")
+	}
+	if _, ok := items.children["type"]; !ok {
+		t.Error("expected \"data.items.type\" node")
+	}
+	errorNode, ok := mask.nodes["error"]
+	if !ok {
+		t.Fatal("expected top level \"error\" node")
+	}
+	errorsNode, ok := errorNode.children["errors"]
+	if !ok {
+		t.Fatal("expected \"error.errors\" node")
+	}
+	if _, ok := errorsNode.children["message"]; !ok {
+		t.Error("expected \"error.errors.message\" node")
+	}
+}
+
+func TestWriteWithFieldMask(t *testing.T) {
+	r := New()
+	r.APIVersion = "0.1"
+	r.Data.Fields = "data.items(color,type)"
+	r.Data.AddItem(CarItem{"red", "SUV"})
+
+	b, err := r.WriteWithFieldMask()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out["apiVersion"] != "0.1" {
+		t.Error("expected apiVersion to be preserved")
+	}
+	data, ok := out["data"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected \"data\" key")
+	}
+	if _, ok := data["kind"]; ok {
+		t.Error("expected \"kind\" to be pruned")
+	}
+	items, ok := data["items"].([]interface{})
+	if !ok || len(items) != 1 {
+		t.Fatal("expected one item")
+	}
+	item := items[0].(map[string]interface{})
+	if item["color"] != "red" {
+		t.Error("expected \"color\" to survive the mask")
+	}
+	if item["type"] != "SUV" {
+		t.Error("expected \"type\" to survive the mask")
+	}
+}
+
+func TestWriteWithFieldMaskPrunesToSingleItemField(t *testing.T) {
+	r := New()
+	r.APIVersion = "0.1"
+	r.Data.Fields = "data.items(color)"
+	r.Data.AddItem(CarItem{"red", "SUV"})
+
+	b, err := r.WriteWithFieldMask()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	data := out["data"].(map[string]interface{})
+	items := data["items"].([]interface{})
+	item := items[0].(map[string]interface{})
+	if item["color"] != "red" {
+		t.Error("expected \"color\" to survive the mask")
+	}
+	if _, ok := item["type"]; ok {
+		t.Error("expected \"type\" to be pruned")
+	}
+}
+
+func TestWriteWithFieldMaskLegacyFlatFields(t *testing.T) {
+	r := New()
+	r.APIVersion = "0.1"
+	r.Data.Kind = "car"
+	r.Data.Etag = "08FQn8-eil7ImA9WxZbFEwo"
+	r.Data.AddField("color")
+	r.Data.AddItem(CarItem{"red", "SUV"})
+
+	b, err := r.WriteWithFieldMask()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	data, ok := out["data"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected \"data\" key to survive the legacy flat mask")
+	}
+	if data["kind"] != "car" {
+		t.Error("expected Data's own fields, e.g. \"kind\", to be unaffected by a flat item field mask")
+	}
+	items, ok := data["items"].([]interface{})
+	if !ok || len(items) != 1 {
+		t.Fatal("expected one item")
+	}
+	item := items[0].(map[string]interface{})
+	if item["color"] != "red" {
+		t.Error("expected \"color\" to survive the mask")
+	}
+	if _, ok := item["type"]; ok {
+		t.Error("expected \"type\" to be pruned from the item")
+	}
+}
+
+func TestReadWithFieldMaskStrictRejectsUnmaskedField(t *testing.T) {
+	r := New()
+	r.Data.Fields = "data.kind"
+	b := []byte(`{"apiVersion":"0.1","data":{"kind":"car","etag":"abc"}}`)
+	if err := r.ReadWithFieldMask(b, true); err == nil {
+		t.Error("expected error for field outside mask")
+	}
+}
+
+func TestReadWithFieldMaskNonStrictIgnoresExtraFields(t *testing.T) {
+	r := New()
+	r.Data.Fields = "data.kind"
+	b := []byte(`{"apiVersion":"0.1","data":{"kind":"car","etag":"abc"}}`)
+	if err := r.ReadWithFieldMask(b, false); err != nil {
+		t.Fatal(err)
+	}
+	if r.Data.Kind != "car" {
+		t.Error("Test failed")
+	}
+}
+
+func TestReadWithFieldMaskStrictFlatMaskChecksItems(t *testing.T) {
+	r := New()
+	r.Data.Fields = "color"
+	b := []byte(`{"apiVersion":"0.1","data":{"items":[{"color":"red","type":"SUV"}]}}`)
+	if err := r.ReadWithFieldMask(b, true); err == nil {
+		t.Error("expected error for item field outside flat mask")
+	}
+}
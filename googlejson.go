@@ -43,6 +43,12 @@ type Response struct {
 
 	// Errors to be returned.
 	Error `json:"error,omitempty"`
+
+	// Operation, if set, marks this Response as a long running
+	// operation rather than (or in addition to) an immediate result.
+	// Named rather than embedded so its fields (notably SelfLink,
+	// which Data also has) aren't promoted onto Response.
+	Operation *Operation `json:"operation,omitempty"`
 }
 
 // Shortcut to create a new Response
@@ -82,12 +88,16 @@ func (r *Response) Write() ([]byte, error) {
 }
 
 // Shortcut to write to an http.ResponseWriter.
+// For content negotiation (pretty-printing, fields overrides,
+// ETag/gzip handling) driven by the incoming request, see
+// WriteToHTTPResponseFor.
 func (r *Response) WriteToHTTPResponse(w http.ResponseWriter) error {
 	b, err := r.Write()
 	if err != nil {
 		return err
 	}
-	return w.Write(b)
+	_, err = w.Write(b)
+	return err
 }
 
 // Data structure holds all information specific to the data in the
@@ -154,6 +164,9 @@ type Data struct {
 
 	// pointer to current item.
 	item int
+
+	// index of the next item NextItemT will return.
+	titem int
 }
 
 // Shortcut to new data object.
@@ -0,0 +1,155 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Done is returned by ItemIterator.Next and PageIterator.Next when
+// iteration is complete.
+var Done = errors.New("googlejson: no more items in iterator")
+
+// PageFetcher retrieves the page of results located at url.
+// ItemIterator and PageIterator always fetch through an HTTPPageFetcher
+// built from the http.Client given to Iterator or Pages; they do not
+// accept any other PageFetcher. The Transport (http.RoundTripper) of
+// that http.Client carries every page fetch, so a custom one replaces
+// the network for them.
+type PageFetcher interface {
+	FetchPage(ctx context.Context, url string) (*Response, error)
+}
+
+// HTTPPageFetcher is the default PageFetcher, fetching pages with an
+// http.Client. A nil Client falls back to http.DefaultClient.
+type HTTPPageFetcher struct {
+	Client *http.Client
+}
+
+// FetchPage issues a GET to url and parses the result as a Response.
+func (f *HTTPPageFetcher) FetchPage(ctx context.Context, url string) (*Response, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromHTTPResponse(*resp)
+}
+
+// PageInfo describes the position of the page currently held by an
+// ItemIterator within the full paginated result set.
+type PageInfo struct {
+	PageIndex  int
+	TotalPages int
+	StartIndex int
+	TotalItems int
+}
+
+// ItemIterator walks the Items of a Data object, automatically
+// following NextLink to fetch further pages once the current page is
+// exhausted.
+type ItemIterator struct {
+	ctx     context.Context
+	fetcher PageFetcher
+	data    *Data
+	index   int
+}
+
+// Iterator returns an ItemIterator over d.Items and any subsequent
+// pages reachable through d.NextLink, fetched with httpClient.
+func (d *Data) Iterator(ctx context.Context, httpClient *http.Client) *ItemIterator {
+	return &ItemIterator{
+		ctx:     ctx,
+		fetcher: &HTTPPageFetcher{Client: httpClient},
+		data:    d,
+		index:   -1,
+	}
+}
+
+// Next unmarshals the next item into i, fetching subsequent pages via
+// NextLink as needed. It returns Done once there are no more items,
+// and the ctx error if ctx is canceled before a page fetch.
+func (it *ItemIterator) Next(i interface{}) error {
+	for {
+		if it.index+1 < it.data.ItemsCount() {
+			it.index++
+			return json.Unmarshal(it.data.Items[it.index], i)
+		}
+		if it.data.NextLink == "" {
+			return Done
+		}
+		if err := it.ctx.Err(); err != nil {
+			return err
+		}
+		next, err := it.fetcher.FetchPage(it.ctx, it.data.NextLink)
+		if err != nil {
+			return err
+		}
+		it.data = &next.Data
+		it.index = -1
+	}
+}
+
+// PageInfo returns pagination details for the page currently held by
+// the iterator.
+func (it *ItemIterator) PageInfo() *PageInfo {
+	return &PageInfo{
+		PageIndex:  it.data.PageIndex,
+		TotalPages: it.data.TotalPages,
+		StartIndex: it.data.StartIndex,
+		TotalItems: it.data.TotalItems,
+	}
+}
+
+// PageIterator yields whole Response pages, following NextLink, rather
+// than individual items.
+type PageIterator struct {
+	ctx     context.Context
+	fetcher PageFetcher
+	next    *Response
+	done    bool
+}
+
+// Pages returns a PageIterator starting at r and following
+// r.Data.NextLink for subsequent pages, fetched with httpClient.
+func (r *Response) Pages(ctx context.Context, httpClient *http.Client) *PageIterator {
+	return &PageIterator{
+		ctx:     ctx,
+		fetcher: &HTTPPageFetcher{Client: httpClient},
+		next:    r,
+	}
+}
+
+// Next returns the next page in the set, or Done once the last page
+// has been returned.
+func (it *PageIterator) Next() (*Response, error) {
+	if it.done || it.next == nil {
+		return nil, Done
+	}
+	page := it.next
+	if page.Data.NextLink == "" {
+		it.done = true
+		return page, nil
+	}
+	if err := it.ctx.Err(); err != nil {
+		return nil, err
+	}
+	next, err := it.fetcher.FetchPage(it.ctx, page.Data.NextLink)
+	if err != nil {
+		return nil, err
+	}
+	it.next = next
+	return page, nil
+}
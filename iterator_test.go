@@ -0,0 +1,125 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+// roundTripperFunc lets a plain function satisfy http.RoundTripper, so
+// tests can substitute canned responses for the pages an iterator
+// would otherwise fetch over the network.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func newFakePageClient(pages map[string]*Response) *http.Client {
+	return &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			page, ok := pages[r.URL.String()]
+			if !ok {
+				return nil, fmt.Errorf("no fake page registered for %s", r.URL)
+			}
+			b, err := page.Write()
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		}),
+	}
+}
+
+func TestItemIteratorFollowsNextLink(t *testing.T) {
+	page2 := New()
+	page2.Data.AddItem(CarItem{"green", "hatchback"})
+
+	page1 := New()
+	page1.Data.NextLink = "https://example.com/cars?page=2"
+	page1.Data.AddItem(CarItem{"red", "SUV"})
+
+	client := newFakePageClient(map[string]*Response{
+		page1.Data.NextLink: page2,
+	})
+
+	it := page1.Data.Iterator(context.Background(), client)
+
+	var got []CarItem
+	for {
+		var c CarItem
+		err := it.Next(&c)
+		if err == Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, c)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(got))
+	}
+	if got[0] != (CarItem{"red", "SUV"}) || got[1] != (CarItem{"green", "hatchback"}) {
+		t.Error("Test failed")
+	}
+}
+
+func TestItemIteratorHonorsContextCancellation(t *testing.T) {
+	page1 := New()
+	page1.Data.NextLink = "https://example.com/cars?page=2"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	it := page1.Data.Iterator(ctx, newFakePageClient(nil))
+	var c CarItem
+	if err := it.Next(&c); err != ctx.Err() {
+		t.Errorf("expected context error, got %v", err)
+	}
+}
+
+func TestPageIterator(t *testing.T) {
+	page2 := New()
+	page2.Data.AddItem(CarItem{"green", "hatchback"})
+
+	page1 := New()
+	page1.Data.NextLink = "https://example.com/cars?page=2"
+	page1.Data.AddItem(CarItem{"red", "SUV"})
+
+	client := newFakePageClient(map[string]*Response{
+		page1.Data.NextLink: page2,
+	})
+
+	it := page1.Pages(context.Background(), client)
+
+	var pages []*Response
+	for {
+		p, err := it.Next()
+		if err == Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, p)
+	}
+
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	if pages[0].Data.ItemsCount() != 1 || pages[1].Data.ItemsCount() != 1 {
+		t.Error("Test failed")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// WriteToHTTPResponseFor writes r to w, honoring the conventions used
+// across Google APIs: ?pp=1 or ?prettyPrint=true indent the JSON with
+// two spaces, ?fields= overrides Data.Fields for the duration of the
+// write, ETag/If-None-Match short circuit to a 304 with an empty
+// body, and Accept-Encoding: gzip compresses the response.
+func (r *Response) WriteToHTTPResponseFor(w http.ResponseWriter, req *http.Request) error {
+	if fields := req.URL.Query().Get("fields"); fields != "" {
+		orig := r.Data.Fields
+		r.Data.Fields = fields
+		defer func() { r.Data.Fields = orig }()
+	}
+
+	if r.Data.Etag != "" {
+		w.Header().Set("ETag", r.Data.Etag)
+		if req.Header.Get("If-None-Match") == r.Data.Etag {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+	}
+
+	b, err := r.WriteWithFieldMask()
+	if err != nil {
+		return err
+	}
+
+	if wantsPrettyPrint(req) {
+		if b, err = prettyPrint(b); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if acceptsGzip(req) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		if _, err := gz.Write(b); err != nil {
+			return err
+		}
+		return gz.Close()
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// prettyPrint re-marshals already-encoded JSON with two-space
+// indentation.
+func prettyPrint(b []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// wantsPrettyPrint reports whether req asked for indented JSON via
+// ?pp=1 or ?prettyPrint=true.
+func wantsPrettyPrint(req *http.Request) bool {
+	q := req.URL.Query()
+	return q.Get("pp") == "1" || q.Get("prettyPrint") == "true"
+}
+
+// acceptsGzip reports whether req's Accept-Encoding header lists gzip.
+func acceptsGzip(req *http.Request) bool {
+	for _, enc := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		if strings.TrimSpace(enc) == "gzip" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newCarResponse() *Response {
+	r := New()
+	r.APIVersion = "0.1"
+	r.Data.Etag = "08FQn8-eil7ImA9WxZbFEwo"
+	r.Data.AddItem(CarItem{"red", "SUV"})
+	return r
+}
+
+func TestWriteToHTTPResponseForPrettyPrint(t *testing.T) {
+	r := newCarResponse()
+	req := httptest.NewRequest("GET", "/cars?pp=1", nil)
+	w := httptest.NewRecorder()
+	if err := r.WriteToHTTPResponseFor(w, req); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(w.Body.String(), "\n  \"") {
+		t.Errorf("expected indented JSON, got %q", w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+}
+
+func TestWriteToHTTPResponseForFieldsOverride(t *testing.T) {
+	r := newCarResponse()
+	req := httptest.NewRequest("GET", "/cars?fields=data.items(color)", nil)
+	w := httptest.NewRecorder()
+	if err := r.WriteToHTTPResponseFor(w, req); err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	data := out["data"].(map[string]interface{})
+	items := data["items"].([]interface{})
+	item := items[0].(map[string]interface{})
+	if _, ok := item["type"]; ok {
+		t.Error("expected \"type\" to be pruned by ?fields= override")
+	}
+	if r.Data.Fields != "" {
+		t.Error("expected ?fields= override not to leak into the reused Response")
+	}
+}
+
+func TestWriteToHTTPResponseForETagNotModified(t *testing.T) {
+	r := newCarResponse()
+	req := httptest.NewRequest("GET", "/cars", nil)
+	req.Header.Set("If-None-Match", r.Data.Etag)
+	w := httptest.NewRecorder()
+	if err := r.WriteToHTTPResponseFor(w, req); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != 304 {
+		t.Errorf("expected 304, got %d", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %q", w.Body.String())
+	}
+}
+
+func TestWriteToHTTPResponseForGzip(t *testing.T) {
+	r := newCarResponse()
+	req := httptest.NewRequest("GET", "/cars", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	if err := r.WriteToHTTPResponseFor(w, req); err != nil {
+		t.Fatal(err)
+	}
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatal("expected gzip Content-Encoding")
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gz.Close()
+	b, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out["apiVersion"] != "0.1" {
+		t.Error("Test failed")
+	}
+}
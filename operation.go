@@ -0,0 +1,143 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Polling backoff bounds used by Operation.Poll.
+const (
+	initialPollBackoff = 500 * time.Millisecond
+	maxPollBackoff     = 30 * time.Second
+)
+
+// Operation represents a Google-style long running operation (LRO):
+// a server hands one back immediately while the real work continues,
+// and the caller polls SelfLink until Done is true.
+type Operation struct {
+	// Name identifies the operation, e.g. "operations/abc123".
+	Name string `json:"name,omitempty"`
+
+	// Done reports whether the operation has finished. While false,
+	// Response and Error are meaningless.
+	Done bool `json:"done,omitempty"`
+
+	// Metadata holds service-specific progress information.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+
+	// Response holds the operation's result once Done is true.
+	// Mutually exclusive with Error.
+	Response json.RawMessage `json:"response,omitempty"`
+
+	// Error holds the reason the operation failed, if any.
+	// Mutually exclusive with Response.
+	Error *Error `json:"error,omitempty"`
+
+	// SelfLink is where the operation can be polled for status.
+	SelfLink string `json:"selfLink,omitempty"`
+}
+
+// NewOperationResponse returns a Response wrapping a newly created,
+// not-yet-complete Operation named name, suitable for a handler to
+// return while the underlying work is still in progress. The caller
+// is responsible for setting SelfLink once the operation's polling
+// URL is known.
+func NewOperationResponse(name string) *Response {
+	r := New()
+	r.Operation = &Operation{Name: name}
+	return r
+}
+
+// CompleteOperation marks o as finished, embedding the JSON encoding
+// of result as its Response.
+func CompleteOperation(o *Operation, result interface{}) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	o.Done = true
+	o.Response = b
+	o.Error = nil
+	return nil
+}
+
+// FailOperation marks o as finished with failure reason reason.
+func FailOperation(o *Operation, reason *Error) {
+	o.Done = true
+	o.Error = reason
+	o.Response = nil
+}
+
+// fetch GETs o.SelfLink and returns the Operation embedded in the
+// response.
+func (o *Operation) fetch(ctx context.Context, client *http.Client) (*Operation, error) {
+	fetcher := &HTTPPageFetcher{Client: client}
+	r, err := fetcher.FetchPage(ctx, o.SelfLink)
+	if err != nil {
+		return nil, err
+	}
+	if r.Operation == nil {
+		return nil, errors.New("googlejson: polled response did not contain an operation")
+	}
+	return r.Operation, nil
+}
+
+// Poll repeatedly GETs o.SelfLink, backing off exponentially with
+// jitter between attempts, until the operation reports Done or ctx is
+// done.
+func (o *Operation) Poll(ctx context.Context, client *http.Client) (*Operation, error) {
+	op := o
+	backoff := initialPollBackoff
+	for !op.Done {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		next, err := o.fetch(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		op = next
+		if op.Done {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = jitter(backoff * 2)
+	}
+	return op, nil
+}
+
+// Wait polls o to completion and unmarshals its Response into v. If
+// the operation finished with an Error, Wait returns an error whose
+// text is that Error's Message; its Code and Errors are not carried.
+func (o *Operation) Wait(ctx context.Context, client *http.Client, v interface{}) error {
+	final, err := o.Poll(ctx, client)
+	if err != nil {
+		return err
+	}
+	if final.Error != nil {
+		return errors.New(final.Error.Message)
+	}
+	return json.Unmarshal(final.Response, v)
+}
+
+// jitter caps d at maxPollBackoff and randomizes it by up to 50% to
+// avoid synchronized polling against the same endpoint.
+func jitter(d time.Duration) time.Duration {
+	if d > maxPollBackoff {
+		d = maxPollBackoff
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,105 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOperationPollUntilDone(t *testing.T) {
+	pending := NewOperationResponse("operations/123")
+	pending.Operation.SelfLink = "https://example.com/operations/123"
+
+	done := New()
+	done.Operation = &Operation{
+		Name:     "operations/123",
+		Done:     true,
+		Response: []byte(`{"color":"red"}`),
+	}
+
+	client := newFakePageClient(map[string]*Response{
+		pending.Operation.SelfLink: done,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	final, err := pending.Operation.Poll(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !final.Done {
+		t.Error("Test failed")
+	}
+}
+
+func TestOperationWaitUnmarshalsResult(t *testing.T) {
+	pending := NewOperationResponse("operations/123")
+	pending.Operation.SelfLink = "https://example.com/operations/123"
+
+	done := New()
+	done.Operation = &Operation{
+		Name:     "operations/123",
+		Done:     true,
+		Response: []byte(`{"color":"red","type":"SUV"}`),
+	}
+
+	client := newFakePageClient(map[string]*Response{
+		pending.Operation.SelfLink: done,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var car CarItem
+	if err := pending.Operation.Wait(ctx, client, &car); err != nil {
+		t.Fatal(err)
+	}
+	if car != (CarItem{"red", "SUV"}) {
+		t.Error("Test failed")
+	}
+}
+
+func TestOperationWaitReturnsError(t *testing.T) {
+	pending := NewOperationResponse("operations/123")
+	pending.Operation.SelfLink = "https://example.com/operations/123"
+
+	done := New()
+	done.Operation = &Operation{
+		Name: "operations/123",
+		Done: true,
+		Error: &Error{
+			Code:    404,
+			Message: "Car Not Found",
+		},
+	}
+
+	client := newFakePageClient(map[string]*Response{
+		pending.Operation.SelfLink: done,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var car CarItem
+	err := pending.Operation.Wait(ctx, client, &car)
+	if err == nil || err.Error() != "Car Not Found" {
+		t.Errorf("expected \"Car Not Found\" error, got %v", err)
+	}
+}
+
+func TestOperationPollHonorsContextCancellation(t *testing.T) {
+	pending := NewOperationResponse("operations/123")
+	pending.Operation.SelfLink = "https://example.com/operations/123"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := pending.Operation.Poll(ctx, newFakePageClient(nil)); err != ctx.Err() {
+		t.Errorf("expected context error, got %v", err)
+	}
+}
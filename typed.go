@@ -0,0 +1,105 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// kindToType and typeToKind record the association made by
+// RegisterKind between a Data.Kind string and a Go type, in both
+// directions. AddItemT and NextItemT validate through typeToKind only;
+// kindToType is not read by this package.
+var (
+	kindToType = make(map[string]reflect.Type)
+	typeToKind = make(map[reflect.Type]string)
+)
+
+// RegisterKind associates kind with the Go type T, so that AddItemT
+// and NextItemT can validate a Data's Kind against the type being
+// marshaled or unmarshaled, instead of every caller hand-rolling a
+// struct and remembering which Kind it goes with.
+func RegisterKind[T any](kind string) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	kindToType[kind] = t
+	typeToKind[t] = kind
+}
+
+// AddItemT marshals v and appends it to d.Items, after checking that
+// v's registered kind (see RegisterKind) matches d.Kind. If d.Kind is
+// unset, it is set to the registered kind.
+func AddItemT[T any](d *Data, v T) error {
+	kind, err := kindFor[T]()
+	if err != nil {
+		return err
+	}
+	if d.Kind == "" {
+		d.Kind = kind
+	} else if d.Kind != kind {
+		return fmt.Errorf("googlejson: data kind %q does not match %q registered for %T", d.Kind, kind, v)
+	}
+	return d.AddItem(v)
+}
+
+// NextItemT unmarshals the next item in d.Items into a value of type
+// T, after checking that d.Kind matches T's registered kind. It
+// returns Done once every item has been returned.
+//
+// NextItemT keeps its own cursor (Data.titem), separate from the one
+// NextItem/CurrentItem/ResetItems share: that older cursor treats
+// index 0 as already "current" rather than "not yet read", so a fresh
+// Data needs a priming CurrentItem call before NextItem works, which
+// would be a surprising requirement to impose on this typed API.
+func NextItemT[T any](d *Data) (T, error) {
+	var zero T
+	kind, err := kindFor[T]()
+	if err != nil {
+		return zero, err
+	}
+	if d.Kind != "" && d.Kind != kind {
+		return zero, fmt.Errorf("googlejson: data kind %q does not match %q registered for %T", d.Kind, kind, zero)
+	}
+	if d.titem >= d.ItemsCount() {
+		return zero, Done
+	}
+	var v T
+	if err := json.Unmarshal(d.Items[d.titem], &v); err != nil {
+		return zero, err
+	}
+	d.titem++
+	return v, nil
+}
+
+func kindFor[T any]() (string, error) {
+	var zero T
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	kind, ok := typeToKind[t]
+	if !ok {
+		return "", fmt.Errorf("googlejson: type %T was never registered with RegisterKind", zero)
+	}
+	return kind, nil
+}
+
+// DecodeAll invokes fn once per item in d.Items with that item's raw
+// JSON. kind is the item's own "kind" field if it has one, otherwise
+// d.Kind, so callers can dispatch on kind even when items array mixes
+// several kinds together.
+func (d *Data) DecodeAll(fn func(kind string, raw json.RawMessage) error) error {
+	for _, raw := range d.Items {
+		kind := d.Kind
+		var probe struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(raw, &probe); err == nil && probe.Kind != "" {
+			kind = probe.Kind
+		}
+		if err := fn(kind, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
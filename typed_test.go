@@ -0,0 +1,117 @@
+// Copyright 2015 Jason Richard Smith.
+// Use of this source code is governed by a GPL-3
+// license that can be found in the LICENSE file.
+
+package googlejson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type TruckItem struct {
+	Kind     string `json:"kind"`
+	Capacity int    `json:"capacity"`
+}
+
+func init() {
+	RegisterKind[CarItem]("car")
+	RegisterKind[TruckItem]("truck")
+}
+
+func TestAddItemTSetsKind(t *testing.T) {
+	d := NewData()
+	if err := AddItemT(d, CarItem{"red", "SUV"}); err != nil {
+		t.Fatal(err)
+	}
+	if d.Kind != "car" {
+		t.Errorf("expected Kind to be set to \"car\", got %q", d.Kind)
+	}
+
+	c := new(CarItem)
+	if err := d.CurrentItem(c); err != nil {
+		t.Fatal(err)
+	}
+	if *c != (CarItem{"red", "SUV"}) {
+		t.Error("Test failed")
+	}
+}
+
+func TestAddItemTThenNextItemT(t *testing.T) {
+	d := NewData()
+	if err := AddItemT(d, CarItem{"red", "SUV"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NextItemT[CarItem](d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != (CarItem{"red", "SUV"}) {
+		t.Error("Test failed")
+	}
+
+	if _, err := NextItemT[CarItem](d); err != Done {
+		t.Errorf("expected Done after the single item, got %v", err)
+	}
+}
+
+func TestNextItemTWalksAllItemsInOrder(t *testing.T) {
+	d := NewData()
+	if err := AddItemT(d, CarItem{"red", "SUV"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddItemT(d, CarItem{"green", "hatchback"}); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := NextItemT[CarItem](d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NextItemT[CarItem](d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != (CarItem{"red", "SUV"}) || second != (CarItem{"green", "hatchback"}) {
+		t.Error("Test failed")
+	}
+	if _, err := NextItemT[CarItem](d); err != Done {
+		t.Errorf("expected Done after both items, got %v", err)
+	}
+}
+
+func TestAddItemTRejectsMismatchedKind(t *testing.T) {
+	d := NewData()
+	d.Kind = "truck"
+	if err := AddItemT(d, CarItem{"red", "SUV"}); err == nil {
+		t.Error("expected an error for mismatched kind")
+	}
+}
+
+func TestNextItemTRejectsMismatchedKind(t *testing.T) {
+	d := NewData()
+	d.Kind = "car"
+	d.AddItem(TruckItem{"truck", 500})
+	if _, err := NextItemT[TruckItem](d); err == nil {
+		t.Error("expected an error for mismatched kind")
+	}
+}
+
+func TestDecodeAllDispatchesPerItemKind(t *testing.T) {
+	d := NewData()
+	d.AddItem(CarItem{"red", "SUV"})
+	d.AddItem(TruckItem{"truck", 500})
+
+	var kinds []string
+	err := d.DecodeAll(func(kind string, raw json.RawMessage) error {
+		kinds = append(kinds, kind)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kinds) != 2 || kinds[0] != "" || kinds[1] != "truck" {
+		t.Errorf("unexpected kinds %v", kinds)
+	}
+}